@@ -18,17 +18,32 @@ package exec
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"math/rand"
 	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/chaosblade-io/chaosblade-spec-go/channel"
 	"github.com/chaosblade-io/chaosblade-spec-go/spec"
 	"github.com/chaosblade-io/chaosblade-spec-go/util"
 
 	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/template"
 )
 
+// fileAppendFormats are the supported --format values controlling how a
+// rendered --content line is framed before being appended.
+var fileAppendFormats = map[string]bool{
+	"raw":    true,
+	"json":   true,
+	"logfmt": true,
+}
+
 const AppendFileBin = "chaos_appendfile"
 
 type FileAppendActionSpec struct {
@@ -63,6 +78,36 @@ func NewFileAppendActionSpec() spec.ExpActionCommandSpec {
 					Desc:   "append content enable base64 encoding",
 					NoArgs: true,
 				},
+				&spec.ExpFlag{
+					Name: "format",
+					Desc: "content format, supports raw, json, logfmt, default raw",
+				},
+				&spec.ExpFlag{
+					Name: "jitter",
+					Desc: "percentage variance applied to --interval, for example 20 randomly floats the interval by +/-20%",
+				},
+				&spec.ExpFlag{
+					Name: "rate",
+					Desc: "append rate in lines/second, mutually exclusive with --interval",
+				},
+				&spec.ExpFlag{
+					Name: "max-bytes",
+					Desc: "stop condition: self-terminate once any target file has grown by this many bytes",
+				},
+				&spec.ExpFlag{
+					Name: "max-lines",
+					Desc: "stop condition: self-terminate once any target file has grown by this many lines",
+				},
+				&spec.ExpFlag{
+					Name:   "truncate-on-start",
+					Desc:   "truncate each target file before appending",
+					NoArgs: true,
+				},
+				&spec.ExpFlag{
+					Name:   "create-if-missing",
+					Desc:   "create a target file instead of failing when it does not exist",
+					NoArgs: true,
+				},
 			},
 			ActionExecutor: &FileAppendActionExecutor{},
 			ActionExample: `
@@ -77,6 +122,18 @@ blade create file append --filepath=/home/logs/nginx.log --content=SEVMTE8gV09ST
 
 # mock interface timeout exception
 blade create file append --filepath=/home/logs/nginx.log --content="@{DATE:+%Y-%m-%d %H:%M:%S} ERROR invoke getUser timeout [@{RANDOM:100-200}]ms abc  mock exception"
+
+# append as a JSON object, one object per matched line, using the per-experiment counter and a generated request id
+blade create file append --filepath=/home/logs/nginx.log --format=json --content="@{DATE:+%Y-%m-%d %H:%M:%S} seq=@{COUNTER} req=@{UUID} ERROR timeout"
+
+# simulate a log storm at 50 lines/second with +/-15% jitter
+blade create file append --filepath=/home/logs/nginx.log --content="@{DATE:+%Y-%m-%d %H:%M:%S} host=@{HOSTNAME} pid=@{PID} ERROR timeout" --rate 50 --jitter 15
+
+# fan out across every rotated log under /var/log, self-terminating once any of them grows by 100MB
+blade create file append --filepath="/var/log/*.log" --content="ERROR disk pressure" --max-bytes 104857600
+
+# append to a file that may not exist yet, truncating it first
+blade create file append --filepath=/home/logs/app.log --content="ERROR mock" --create-if-missing --truncate-on-start
 `,
 			ActionPrograms:   []string{AppendFileBin},
 			ActionCategories: []string{category.SystemFile},
@@ -97,13 +154,30 @@ func (*FileAppendActionSpec) ShortDesc() string {
 }
 
 func (f *FileAppendActionSpec) LongDesc() string {
-	return "File content append. "
+	return "File content append. The --content template supports @{DATE}, @{RANDOM}, @{COUNTER}, @{UUID}, @{HOSTNAME}, @{PID} and @{ENV:VAR} variables, --format controls whether each appended line is framed as raw text, a JSON object or a logfmt key=value line, and --filepath accepts a comma-separated list or glob so a single experiment can fan out to several files."
 }
 
 type FileAppendActionExecutor struct {
 	channel spec.Channel
 }
 
+// appendOptions collects the parsed, validated flags for a single file append
+// experiment. It is applied identically to every target matched by --filepath.
+type appendOptions struct {
+	content         string
+	count           int
+	interval        int
+	escape          bool
+	enableBase64    bool
+	format          string
+	jitter          int
+	rate            int
+	maxBytes        int64
+	maxLines        int64
+	truncateOnStart bool
+	createIfMissing bool
+}
+
 func (*FileAppendActionExecutor) Name() string {
 	return "append"
 }
@@ -118,67 +192,433 @@ func (f *FileAppendActionExecutor) Exec(uid string, ctx context.Context, model *
 		return spec.ReturnFail(spec.Code[spec.ServerError], "channel is nil")
 	}
 
-	filepath := model.ActionFlags["filepath"]
+	filepathExpr := model.ActionFlags["filepath"]
 	if _, ok := spec.IsDestroy(ctx); ok {
-		return f.stop(filepath, ctx)
+		cancelAppendLoop(uid)
+		cancelStopConditionMonitor(uid)
+		targets, err := resolveFileTargets(filepathExpr, true)
+		if err != nil {
+			return spec.ReturnFail(spec.Code[spec.IllegalParameters], err.Error())
+		}
+		return f.stop(targets, ctx)
 	}
 
-	// default 1
-	count := 1
-	// 1000 ms
-	interval := 1
-
-	content := model.ActionFlags["content"]
+	opts := appendOptions{
+		// default 1
+		count: 1,
+		// 1000 ms
+		interval: 1,
+		format:   "raw",
+	}
+	opts.content = model.ActionFlags["content"]
 	countStr := model.ActionFlags["count"]
 	intervalStr := model.ActionFlags["interval"]
 	if countStr != "" {
-		var err error
-		count, err = strconv.Atoi(countStr)
-		if err != nil || count < 1 {
+		opts.count, err = strconv.Atoi(countStr)
+		if err != nil || opts.count < 1 {
 			return spec.ReturnFail(spec.Code[spec.IllegalParameters], "--count value must be a positive integer")
 		}
 	}
 	if intervalStr != "" {
-		var err error
-		interval, err = strconv.Atoi(intervalStr)
-		if err != nil || interval < 1 {
+		opts.interval, err = strconv.Atoi(intervalStr)
+		if err != nil || opts.interval < 1 {
 			return spec.ReturnFail(spec.Code[spec.IllegalParameters], "--interval value must be a positive integer")
 		}
 	}
 
-	escape := model.ActionFlags["escape"] == "true"
-	enableBase64 := model.ActionFlags["enable-base64"] == "true"
+	opts.escape = model.ActionFlags["escape"] == "true"
+	opts.enableBase64 = model.ActionFlags["enable-base64"] == "true"
+	opts.truncateOnStart = model.ActionFlags["truncate-on-start"] == "true"
+	opts.createIfMissing = model.ActionFlags["create-if-missing"] == "true"
+	if opts.escape {
+		// --escape renders a single line as-is; --count doesn't apply to it.
+		opts.count = 1
+	}
 
-	if !util.IsExist(filepath) {
+	if format := model.ActionFlags["format"]; format != "" {
+		opts.format = format
+	}
+	if !fileAppendFormats[opts.format] {
 		return spec.ReturnFail(spec.Code[spec.IllegalParameters],
-			fmt.Sprintf("the %s file does not exist", filepath))
+			fmt.Sprintf("--format value must be one of raw, json, logfmt, got %s", opts.format))
+	}
+
+	rateStr := model.ActionFlags["rate"]
+	if rateStr != "" && intervalStr != "" {
+		return spec.ReturnFail(spec.Code[spec.IllegalParameters], "--rate and --interval are mutually exclusive")
+	}
+	if rateStr != "" {
+		opts.rate, err = strconv.Atoi(rateStr)
+		if err != nil || opts.rate < 1 {
+			return spec.ReturnFail(spec.Code[spec.IllegalParameters], "--rate value must be a positive integer")
+		}
 	}
 
-	return f.start(filepath, content, count, interval, escape, enableBase64, ctx)
+	jitterStr := model.ActionFlags["jitter"]
+	if jitterStr != "" {
+		opts.jitter, err = strconv.Atoi(jitterStr)
+		if err != nil || opts.jitter < 0 || opts.jitter > 100 {
+			return spec.ReturnFail(spec.Code[spec.IllegalParameters], "--jitter value must be an integer between 0 and 100")
+		}
+	}
+
+	if maxBytesStr := model.ActionFlags["max-bytes"]; maxBytesStr != "" {
+		opts.maxBytes, err = strconv.ParseInt(maxBytesStr, 10, 64)
+		if err != nil || opts.maxBytes < 1 {
+			return spec.ReturnFail(spec.Code[spec.IllegalParameters], "--max-bytes value must be a positive integer")
+		}
+	}
+	if maxLinesStr := model.ActionFlags["max-lines"]; maxLinesStr != "" {
+		opts.maxLines, err = strconv.ParseInt(maxLinesStr, 10, 64)
+		if err != nil || opts.maxLines < 1 {
+			return spec.ReturnFail(spec.Code[spec.IllegalParameters], "--max-lines value must be a positive integer")
+		}
+	}
+
+	templateContent := opts.content
+	if opts.enableBase64 {
+		if decoded, decodeErr := base64.StdEncoding.DecodeString(opts.content); decodeErr == nil {
+			templateContent = string(decoded)
+		}
+	}
+	if err := template.Validate(templateContent); err != nil {
+		return spec.ReturnFail(spec.Code[spec.IllegalParameters], err.Error())
+	}
+
+	targets, err := resolveFileTargets(filepathExpr, opts.createIfMissing)
+	if err != nil {
+		return spec.ReturnFail(spec.Code[spec.IllegalParameters], err.Error())
+	}
+	if !opts.createIfMissing {
+		for _, target := range targets {
+			if !util.IsExist(target) {
+				return spec.ReturnFail(spec.Code[spec.IllegalParameters],
+					fmt.Sprintf("the %s file does not exist", target))
+			}
+		}
+	}
+
+	var baselines map[string]fileGrowth
+	if opts.maxBytes > 0 || opts.maxLines > 0 {
+		// Captured before the first append so that line is counted towards
+		// --max-bytes/--max-lines growth too, not just the lines after it.
+		// --truncate-on-start empties every target before that append, so
+		// growth is then counted from an empty file.
+		if opts.truncateOnStart {
+			baselines = make(map[string]fileGrowth, len(targets))
+		} else {
+			baselines = measureFileGrowthBaselines(f, targets, ctx)
+		}
+	}
+
+	response := f.start(uid, targets, opts, ctx)
+	if response.Success && baselines != nil {
+		scheduleStopConditionMonitor(f, uid, targets, opts, baselines)
+	}
+	return response
 }
 
-func (f *FileAppendActionExecutor) start(filepath string, content string, count int, interval int, escape bool, enableBase64 bool, ctx context.Context) *spec.Response {
-	flags := fmt.Sprintf(`--start --filepath "%s" --content "%s" --count %d --interval %d --debug=%t`, filepath, content, count, interval, util.Debug)
-	if escape {
-		flags = fmt.Sprintf("%s --escape=true", flags)
+// start renders and appends the first line to every target synchronously, so
+// a template/format error or an unreachable target is reported immediately,
+// then - if --count/--rate call for more than one line - hands the remaining
+// lines to a background loop. Every line is still appended through
+// chaos_appendfile via the channel, exactly like the first one, so this
+// keeps working against remote targets instead of writing the file locally.
+func (f *FileAppendActionExecutor) start(uid string, targets []string, opts appendOptions, ctx context.Context) *spec.Response {
+	counter := template.NewCounter()
+	identity := f.resolveIdentity(ctx)
+	var failures []string
+	started := 0
+	for _, target := range targets {
+		response := f.appendLine(target, opts, counter, identity, true, ctx)
+		if !response.Success {
+			failures = append(failures, fmt.Sprintf("%s: %s", target, response.Err))
+			continue
+		}
+		started++
+	}
+	if len(failures) > 0 {
+		return spec.ReturnFail(spec.Code[spec.ServerError],
+			fmt.Sprintf("append started on %d/%d file(s), failed: %s", started, len(targets), strings.Join(failures, "; ")))
 	}
-	if enableBase64 {
-		flags = fmt.Sprintf("%s --enable-base64=true", flags)
+
+	if opts.rate > 0 || opts.count > 1 {
+		scheduleAppendLoop(f, uid, targets, opts, counter, identity)
+	}
+	return spec.ReturnSuccess(fmt.Sprintf("append started on %d file(s)", started))
+}
+
+// resolveIdentity resolves the @{HOSTNAME}/@{PID} values rendered content
+// should carry. It asks the channel rather than calling os.Hostname()/
+// os.Getpid(), so a remote target's own identity is rendered instead of the
+// blade controller's.
+func (f *FileAppendActionExecutor) resolveIdentity(ctx context.Context) template.Identity {
+	var identity template.Identity
+	if response := f.channel.Run(ctx, "hostname", ""); response.Success {
+		identity.Hostname = strings.TrimSpace(fmt.Sprintf("%v", response.Result))
+	}
+	if response := f.channel.Run(ctx, "sh", `-c "echo $$"`); response.Success {
+		identity.PID = strings.TrimSpace(fmt.Sprintf("%v", response.Result))
+	}
+	return identity
+}
+
+// appendLine renders opts.content (resolving its @{...} template variables),
+// frames it per opts.format, and appends the single resulting line to target.
+// first carries --truncate-on-start/--create-if-missing, which only apply to
+// a target's very first append and are applied via prepareTarget, since
+// chaos_appendfile has no way to tell it apart from an unknown flag.
+func (f *FileAppendActionExecutor) appendLine(target string, opts appendOptions, counter *template.Counter, identity template.Identity, first bool, ctx context.Context) *spec.Response {
+	if first {
+		if response := f.prepareTarget(ctx, target, opts); !response.Success {
+			return response
+		}
+	}
+
+	content := opts.content
+	if opts.enableBase64 {
+		decoded, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return spec.ReturnFail(spec.Code[spec.IllegalParameters], fmt.Sprintf("--content is not valid base64: %s", err.Error()))
+		}
+		content = string(decoded)
+	}
+	rendered := template.Render(content, counter, identity)
+	line, err := template.Frame(rendered, opts.format)
+	if err != nil {
+		return spec.ReturnFail(spec.Code[spec.IllegalParameters], err.Error())
+	}
+
+	flags := fmt.Sprintf(`--start --filepath "%s" --content "%s" --debug=%t`, target, escapeDoubleQuotes(line), util.Debug)
+	if opts.escape {
+		flags = fmt.Sprintf("%s --escape=true", flags)
 	}
 	return f.channel.Run(ctx, path.Join(f.channel.GetScriptPath(), AppendFileBin), flags)
 }
 
-func (f *FileAppendActionExecutor) stop(filepath string, ctx context.Context) *spec.Response {
-	return f.channel.Run(ctx, path.Join(f.channel.GetScriptPath(), AppendFileBin),
-		fmt.Sprintf(`--stop --filepath %s --debug=%t`, filepath, util.Debug))
+// prepareTarget truncates and/or creates target before its first line is
+// appended, so --truncate-on-start/--create-if-missing actually take effect
+// instead of being forwarded to chaos_appendfile as flags it doesn't
+// implement. Like every other operation in this file, it goes through
+// f.channel rather than the local filesystem, so it still does the right
+// thing when target lives on a remote channel.
+func (f *FileAppendActionExecutor) prepareTarget(ctx context.Context, target string, opts appendOptions) *spec.Response {
+	if !opts.truncateOnStart && !opts.createIfMissing {
+		return spec.ReturnSuccess("")
+	}
+	if opts.truncateOnStart {
+		// truncate creates the file if it doesn't exist yet, covering
+		// --create-if-missing too when both flags are set.
+		return f.channel.Run(ctx, "truncate", fmt.Sprintf(`-s 0 "%s"`, target))
+	}
+	return f.channel.Run(ctx, "touch", fmt.Sprintf(`"%s"`, target))
+}
+
+// escapeDoubleQuotes makes line safe to embed inside the double-quoted
+// --content "..." shell argument: --format=json/logfmt always render literal
+// `"` characters, which would otherwise prematurely close the argument and
+// corrupt or fail the append.
+func escapeDoubleQuotes(line string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(line)
+}
+
+// appendRunners tracks the running --count/--rate append loops keyed by
+// experiment uid, so an explicit destroy can cancel the loop instead of
+// racing it, mirroring stopConditionMonitors below.
+var appendRunners sync.Map
+
+// scheduleAppendLoop starts a supervisor goroutine that appends the
+// remaining lines (opts.count-1 more, or indefinitely for --rate) at the
+// cadence opts.interval/--rate, jittered by opts.jitter, until cancelled.
+func scheduleAppendLoop(f *FileAppendActionExecutor, uid string, targets []string, opts appendOptions, counter *template.Counter, identity template.Identity) {
+	loopCtx, cancel := context.WithCancel(context.Background())
+	appendRunners.Store(uid, cancel)
+	go func() {
+		defer appendRunners.Delete(uid)
+		remaining := opts.count - 1
+		for opts.rate > 0 || remaining > 0 {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-time.After(nextAppendInterval(opts)):
+			}
+			for _, target := range targets {
+				f.appendLine(target, opts, counter, identity, false, context.Background())
+			}
+			if opts.rate == 0 {
+				remaining--
+			}
+		}
+	}()
+}
+
+func cancelAppendLoop(uid string) {
+	if cancel, ok := appendRunners.Load(uid); ok {
+		cancel.(context.CancelFunc)()
+		appendRunners.Delete(uid)
+	}
+}
+
+// nextAppendInterval derives the wait before the next append from --rate (if
+// set) or --interval, then applies +/-opts.jitter percent of random spread.
+func nextAppendInterval(opts appendOptions) time.Duration {
+	base := time.Duration(opts.interval) * time.Second
+	if opts.rate > 0 {
+		base = time.Second / time.Duration(opts.rate)
+	}
+	if opts.jitter <= 0 {
+		return base
+	}
+	spread := int64(base) * int64(opts.jitter) / 100
+	if spread <= 0 {
+		return base
+	}
+	offset := rand.Int63n(2*spread+1) - spread
+	return time.Duration(int64(base) + offset)
+}
+
+func (f *FileAppendActionExecutor) stop(targets []string, ctx context.Context) *spec.Response {
+	var failures []string
+	stopped := 0
+	for _, target := range targets {
+		response := f.channel.Run(ctx, path.Join(f.channel.GetScriptPath(), AppendFileBin),
+			fmt.Sprintf(`--stop --filepath %s --debug=%t`, target, util.Debug))
+		if !response.Success {
+			failures = append(failures, fmt.Sprintf("%s: %s", target, response.Err))
+			continue
+		}
+		stopped++
+	}
+	if len(failures) > 0 {
+		return spec.ReturnFail(spec.Code[spec.ServerError],
+			fmt.Sprintf("append stopped on %d/%d file(s), failed: %s", stopped, len(targets), strings.Join(failures, "; ")))
+	}
+	return spec.ReturnSuccess(fmt.Sprintf("append stopped on %d file(s)", stopped))
 }
 
 func (f *FileAppendActionExecutor) SetChannel(channel spec.Channel) {
 	f.channel = channel
 }
 
+// stopConditionPollInterval is how often a running experiment's target files
+// are checked against --max-bytes/--max-lines.
+const stopConditionPollInterval = time.Second
+
+// stopConditionMonitors tracks the running --max-bytes/--max-lines watchers
+// keyed by experiment uid, so an explicit destroy can cancel the watcher
+// instead of racing it.
+var stopConditionMonitors sync.Map
+
+// fileGrowth is a target file's size and line count at a point in time, used
+// to measure how much it has grown since the experiment started.
+type fileGrowth struct {
+	bytes int64
+	lines int64
+}
+
+// measureFileGrowthBaselines snapshots every target's size and line count so
+// growth can be measured against it later. Callers must capture this before
+// the first line is appended, or that line's growth is never counted.
+func measureFileGrowthBaselines(f *FileAppendActionExecutor, targets []string, ctx context.Context) map[string]fileGrowth {
+	baselines := make(map[string]fileGrowth, len(targets))
+	for _, target := range targets {
+		baselines[target] = measureFileGrowth(f, target, ctx)
+	}
+	return baselines
+}
+
+// scheduleStopConditionMonitor starts a supervisor goroutine that polls each
+// target's growth against baselines and self-terminates the experiment, via
+// the same stop path a destroy call would use, once any target has grown
+// past --max-bytes or --max-lines. It also cancels the still-running append
+// loop itself (cancelAppendLoop) so self-termination actually stops new
+// lines from being appended instead of only stopping chaos_appendfile's own
+// bookkeeping while scheduleAppendLoop keeps firing past the declared limit.
+//
+// Note: there is no separate "status subsystem" in this vendored
+// exec-os plugin layer to report completion through - that lives in the
+// blade CLI's record/db layer above this package, which this package has no
+// handle on. Self-termination is surfaced the same way process_stop.go's
+// scheduleAutoRecover surfaces its own auto-recovery: by acting (stopping
+// the experiment) rather than by a second status call.
+func scheduleStopConditionMonitor(f *FileAppendActionExecutor, uid string, targets []string, opts appendOptions, baselines map[string]fileGrowth) {
+	monitorCtx, cancel := context.WithCancel(context.Background())
+	stopConditionMonitors.Store(uid, cancel)
+	go func() {
+		defer stopConditionMonitors.Delete(uid)
+		ticker := time.NewTicker(stopConditionPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-monitorCtx.Done():
+				return
+			case <-ticker.C:
+				for _, target := range targets {
+					current := measureFileGrowth(f, target, context.Background())
+					baseline := baselines[target]
+					if growthExceeded(baseline, current, opts) {
+						cancelAppendLoop(uid)
+						f.stop(targets, context.Background())
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// growthExceeded reports whether current has grown past baseline by at
+// least opts.maxBytes bytes or opts.maxLines lines, whichever stop
+// conditions are set.
+func growthExceeded(baseline, current fileGrowth, opts appendOptions) bool {
+	grownBytes := current.bytes - baseline.bytes
+	grownLines := current.lines - baseline.lines
+	return (opts.maxBytes > 0 && grownBytes >= opts.maxBytes) ||
+		(opts.maxLines > 0 && grownLines >= opts.maxLines)
+}
+
+func cancelStopConditionMonitor(uid string) {
+	if cancel, ok := stopConditionMonitors.Load(uid); ok {
+		cancel.(context.CancelFunc)()
+		stopConditionMonitors.Delete(uid)
+	}
+}
+
+// measureFileGrowth returns the current size and line count of target,
+// treating a missing or unreadable file as zero so a file that hasn't been
+// created yet by --create-if-missing doesn't fault the monitor. Like
+// prepareTarget, this goes through f.channel so it measures the file the
+// experiment is actually acting on, even behind a remote channel.
+func measureFileGrowth(f *FileAppendActionExecutor, target string, ctx context.Context) fileGrowth {
+	sizeResponse := f.channel.Run(ctx, "stat", fmt.Sprintf(`-c %%s "%s"`, target))
+	if !sizeResponse.Success {
+		return fileGrowth{}
+	}
+	bytes, err := strconv.ParseInt(strings.TrimSpace(fmt.Sprintf("%v", sizeResponse.Result)), 10, 64)
+	if err != nil {
+		return fileGrowth{}
+	}
+	return fileGrowth{bytes: bytes, lines: countLines(f, target, ctx)}
+}
+
+func countLines(f *FileAppendActionExecutor, target string, ctx context.Context) int64 {
+	response := f.channel.Run(ctx, "wc", fmt.Sprintf(`-l "%s"`, target))
+	if !response.Success {
+		return 0
+	}
+	fields := strings.Fields(fmt.Sprintf("%v", response.Result))
+	if len(fields) == 0 {
+		return 0
+	}
+	lines, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return lines
+}
+
 func checkAppendFileExpEnv() error {
-	commands := []string{"echo", "kill"}
+	commands := []string{"echo", "kill", "truncate", "touch", "stat", "wc"}
 	for _, command := range commands {
 		if !channel.NewLocalChannel().IsCommandAvailable(command) {
 			return fmt.Errorf("%s command not found", command)
@@ -186,3 +626,47 @@ func checkAppendFileExpEnv() error {
 	}
 	return nil
 }
+
+// resolveFileTargets expands a --filepath value, which may be a single path,
+// a comma-separated list, or a glob pattern, into the distinct list of target
+// files an append experiment should fan out to. A literal (non-glob) path is
+// always kept even if it does not exist yet, so --create-if-missing can
+// create it; a glob segment that matches nothing is an error unless
+// allowMissing is set, in which case it contributes no targets.
+func resolveFileTargets(pathExpr string, allowMissing bool) ([]string, error) {
+	if pathExpr == "" {
+		return nil, fmt.Errorf("--filepath is required")
+	}
+	var targets []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(pathExpr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.ContainsAny(part, "*?[") {
+			matches, err := filepath.Glob(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --filepath pattern %s: %s", part, err.Error())
+			}
+			if len(matches) == 0 && !allowMissing {
+				return nil, fmt.Errorf("no file matched --filepath pattern %s", part)
+			}
+			for _, match := range matches {
+				if !seen[match] {
+					seen[match] = true
+					targets = append(targets, match)
+				}
+			}
+			continue
+		}
+		if !seen[part] {
+			seen[part] = true
+			targets = append(targets, part)
+		}
+	}
+	if len(targets) == 0 && !allowMissing {
+		return nil, fmt.Errorf("no file matched --filepath %s", pathExpr)
+	}
+	return targets, nil
+}
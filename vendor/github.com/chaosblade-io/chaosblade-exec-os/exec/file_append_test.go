@@ -0,0 +1,200 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestResolveFileTargets(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.log", "b.log", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("failed to seed %s: %s", name, err.Error())
+		}
+	}
+
+	tests := []struct {
+		name         string
+		pathExpr     string
+		allowMissing bool
+		want         []string
+		wantErr      bool
+	}{
+		{
+			name:     "single literal path",
+			pathExpr: filepath.Join(dir, "a.log"),
+			want:     []string{filepath.Join(dir, "a.log")},
+		},
+		{
+			name:     "comma separated list",
+			pathExpr: filepath.Join(dir, "a.log") + "," + filepath.Join(dir, "c.txt"),
+			want:     []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "c.txt")},
+		},
+		{
+			name:     "glob expands to every match",
+			pathExpr: filepath.Join(dir, "*.log"),
+			want:     []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")},
+		},
+		{
+			name:         "missing literal path is kept when allowMissing",
+			pathExpr:     filepath.Join(dir, "missing.log"),
+			allowMissing: true,
+			want:         []string{filepath.Join(dir, "missing.log")},
+		},
+		{
+			name:     "zero-match glob is an error when allowMissing is false",
+			pathExpr: filepath.Join(dir, "*.nope"),
+			wantErr:  true,
+		},
+		{
+			name:         "zero-match glob is tolerated when allowMissing is true",
+			pathExpr:     filepath.Join(dir, "*.nope"),
+			allowMissing: true,
+			want:         nil,
+		},
+		{
+			name:    "empty --filepath is an error",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveFileTargets(tt.pathExpr, tt.allowMissing)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("resolveFileTargets(%q, %v) = %v, want error", tt.pathExpr, tt.allowMissing, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveFileTargets(%q, %v) returned error: %s", tt.pathExpr, tt.allowMissing, err.Error())
+			}
+			sort.Strings(got)
+			sort.Strings(tt.want)
+			if len(got) != len(tt.want) {
+				t.Fatalf("resolveFileTargets(%q, %v) = %v, want %v", tt.pathExpr, tt.allowMissing, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("resolveFileTargets(%q, %v) = %v, want %v", tt.pathExpr, tt.allowMissing, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGrowthExceeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline fileGrowth
+		current  fileGrowth
+		opts     appendOptions
+		want     bool
+	}{
+		{
+			name:     "no stop condition configured never exceeds",
+			baseline: fileGrowth{bytes: 0, lines: 0},
+			current:  fileGrowth{bytes: 1 << 30, lines: 1 << 20},
+			opts:     appendOptions{},
+			want:     false,
+		},
+		{
+			name:     "max-bytes not yet reached",
+			baseline: fileGrowth{bytes: 100},
+			current:  fileGrowth{bytes: 150},
+			opts:     appendOptions{maxBytes: 100},
+			want:     false,
+		},
+		{
+			name:     "max-bytes reached exactly",
+			baseline: fileGrowth{bytes: 100},
+			current:  fileGrowth{bytes: 200},
+			opts:     appendOptions{maxBytes: 100},
+			want:     true,
+		},
+		{
+			name:     "max-lines reached",
+			baseline: fileGrowth{lines: 10},
+			current:  fileGrowth{lines: 25},
+			opts:     appendOptions{maxLines: 10},
+			want:     true,
+		},
+		{
+			name:     "only one of two conditions reached still exceeds",
+			baseline: fileGrowth{bytes: 0, lines: 0},
+			current:  fileGrowth{bytes: 5, lines: 100},
+			opts:     appendOptions{maxBytes: 1000, maxLines: 50},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := growthExceeded(tt.baseline, tt.current, tt.opts); got != tt.want {
+				t.Fatalf("growthExceeded(%+v, %+v, %+v) = %v, want %v", tt.baseline, tt.current, tt.opts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelAppendLoop(t *testing.T) {
+	uid := "test-uid-append-loop"
+	cancelled := false
+	_, cancel := context.WithCancel(context.Background())
+	appendRunners.Store(uid, context.CancelFunc(func() {
+		cancelled = true
+		cancel()
+	}))
+
+	cancelAppendLoop(uid)
+
+	if !cancelled {
+		t.Fatal("cancelAppendLoop did not invoke the stored cancel func")
+	}
+	if _, ok := appendRunners.Load(uid); ok {
+		t.Fatal("cancelAppendLoop left the uid in appendRunners")
+	}
+
+	// Calling it again once already cancelled/removed must be a no-op, not a panic.
+	cancelAppendLoop(uid)
+}
+
+func TestCancelStopConditionMonitor(t *testing.T) {
+	uid := "test-uid-stop-condition"
+	cancelled := false
+	stopConditionMonitors.Store(uid, context.CancelFunc(func() {
+		cancelled = true
+	}))
+
+	cancelStopConditionMonitor(uid)
+
+	if !cancelled {
+		t.Fatal("cancelStopConditionMonitor did not invoke the stored cancel func")
+	}
+	if _, ok := stopConditionMonitors.Load(uid); ok {
+		t.Fatal("cancelStopConditionMonitor left the uid in stopConditionMonitors")
+	}
+
+	// Calling it again once already cancelled/removed must be a no-op, not a panic.
+	cancelStopConditionMonitor(uid)
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package template resolves the @{...} content template variables supported
+// by the append-file actions and frames a resolved line into its output
+// format (raw, json, logfmt), so every caller that appends templated content
+// - today the Go executor, and any other append surface this package grows
+// to cover - renders it identically.
+package template
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Variables are the @{NAME} or @{NAME:ARG} tokens this package knows how to
+// resolve. ENV is handled separately since it takes a caller-defined suffix
+// (@{ENV:VAR}).
+var Variables = map[string]bool{
+	"DATE":     true,
+	"RANDOM":   true,
+	"COUNTER":  true,
+	"UUID":     true,
+	"HOSTNAME": true,
+	"PID":      true,
+}
+
+var tokenPattern = regexp.MustCompile(`@\{([^{}]+)\}`)
+
+// Validate reports an error if content references an @{...} template
+// variable that Render does not know how to resolve.
+func Validate(content string) error {
+	for _, match := range tokenPattern.FindAllStringSubmatch(content, -1) {
+		token := match[1]
+		name := token
+		if idx := strings.IndexByte(token, ':'); idx >= 0 {
+			name = token[:idx]
+		}
+		if name == "ENV" {
+			continue
+		}
+		if !Variables[name] {
+			return fmt.Errorf("unsupported template variable @{%s}", token)
+		}
+	}
+	return nil
+}
+
+// Counter is a monotonic, concurrency-safe sequence shared by every
+// @{COUNTER} token rendered for one experiment, so multiple target files
+// draw from one increasing sequence rather than each restarting at 1.
+type Counter struct {
+	value int64
+}
+
+// NewCounter returns a Counter starting at 0; its first Render call yields 1.
+func NewCounter() *Counter {
+	return &Counter{}
+}
+
+func (c *Counter) next() int64 {
+	return atomic.AddInt64(&c.value, 1)
+}
+
+// Identity carries the @{HOSTNAME}/@{PID} values Render substitutes. Callers
+// resolve these themselves - typically against the actual experiment target,
+// which may be reached through a remote channel - rather than this package
+// defaulting to os.Hostname()/os.Getpid() of whatever process is rendering,
+// which would report the blade controller's identity instead of the
+// target's.
+type Identity struct {
+	Hostname string
+	PID      string
+}
+
+// Render resolves every @{...} token in content: @{DATE} and @{DATE:+FORMAT}
+// with a strftime-style format, @{RANDOM:MIN-MAX}, @{COUNTER} drawn from
+// counter, @{UUID}, @{HOSTNAME}/@{PID} from identity, and @{ENV:VAR}. A token
+// this package doesn't recognize is left untouched; Validate should be
+// called first to reject those up front.
+func Render(content string, counter *Counter, identity Identity) string {
+	return tokenPattern.ReplaceAllStringFunc(content, func(token string) string {
+		inner := token[2 : len(token)-1]
+		name := inner
+		arg := ""
+		if idx := strings.IndexByte(inner, ':'); idx >= 0 {
+			name = inner[:idx]
+			arg = inner[idx+1:]
+		}
+		switch name {
+		case "DATE":
+			return renderDate(arg)
+		case "RANDOM":
+			return renderRandom(arg)
+		case "COUNTER":
+			return strconv.FormatInt(counter.next(), 10)
+		case "UUID":
+			return newUUID()
+		case "HOSTNAME":
+			return identity.Hostname
+		case "PID":
+			return identity.PID
+		case "ENV":
+			return os.Getenv(arg)
+		default:
+			return token
+		}
+	})
+}
+
+var strftimeDirectives = strings.NewReplacer(
+	"%Y", "2006", "%m", "01", "%d", "02",
+	"%H", "15", "%M", "04", "%S", "05",
+)
+
+func renderDate(arg string) string {
+	layout := strftimeDirectives.Replace(strings.TrimPrefix(arg, "+"))
+	if layout == "" {
+		layout = "2006-01-02 15:04:05"
+	}
+	return time.Now().Format(layout)
+}
+
+func renderRandom(arg string) string {
+	min, max, ok := parseRange(arg)
+	if !ok {
+		return fmt.Sprintf("@{RANDOM:%s}", arg)
+	}
+	if max < min {
+		min, max = max, min
+	}
+	return strconv.Itoa(min + rand.Intn(max-min+1))
+}
+
+func parseRange(arg string) (int, int, bool) {
+	parts := strings.SplitN(arg, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	min, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+	max, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return min, max, true
+}
+
+func newUUID() string {
+	var b [16]byte
+	if _, err := cryptorand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Frame renders a resolved line in the requested output format: raw passes
+// it through unchanged, json wraps it as {"message": "<line>"}, and logfmt
+// renders it as a message="<line>" key-value pair.
+func Frame(line string, format string) (string, error) {
+	switch format {
+	case "", "raw":
+		return line, nil
+	case "json":
+		encoded, err := json.Marshal(map[string]string{"message": line})
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	case "logfmt":
+		return fmt.Sprintf("message=%q", line), nil
+	default:
+		return "", fmt.Errorf("unsupported format %s", format)
+	}
+}